@@ -0,0 +1,25 @@
+package p2p
+
+// Config for the p2p service. These settings are set on the application
+// level, so this config is publicly exported and expected to be set via
+// flags or a launcher script.
+type Config struct {
+	NoDiscovery          bool
+	StaticPeers          []string
+	BootstrapNodeAddr    []string
+	Discv5BootStrapAddr  []string
+	RelayNodeAddr        string
+	DataDir              string
+	LocalIP              string
+	HostAddress          string
+	PrivateKey           string
+	TCPPort              uint
+	UDPPort              uint
+	MaxPeers             uint
+	Encoding             string
+	// NAT describes how this node should discover and advertise its
+	// externally reachable address. Accepted values are "upnp", "pmp",
+	// "extip:<ip>", "any", and "none" (the default, which disables NAT
+	// traversal entirely).
+	NAT string
+}
@@ -0,0 +1,185 @@
+package p2p
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/discover"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/prysmaticlabs/prysm/shared/params"
+)
+
+// createListener instantiates a discv5 UDP listener bound to ipAddr and
+// cfg.UDPPort, advertising an ENR that carries both the current eth2 fork
+// digest and, if NAT traversal is configured, an externally reachable
+// address rather than the raw bind address.
+func (s *Service) createListener(ipAddr net.IP, privKey *ecdsa.PrivateKey) *discover.UDPv5 {
+	conn, err := s.discv5Transport(ipAddr, int(s.cfg.UDPPort))
+	if err != nil {
+		log.WithError(err).Fatal("Could not open discv5 transport")
+	}
+
+	localNode, err := s.createLocalNode(privKey, ipAddr, int(s.cfg.UDPPort), int(s.cfg.TCPPort))
+	if err != nil {
+		log.WithError(err).Fatal("Could not create local node")
+	}
+
+	if s.nat != nil {
+		go s.watchExternalIP(localNode)
+	}
+
+	network, err := discover.ListenV5(conn, localNode, discover.Config{PrivateKey: privKey})
+	if err != nil {
+		log.WithError(err).Fatal("Could not start discv5 listener")
+	}
+	return network
+}
+
+// discv5Transport returns the net.PacketConn discv5 should run its wire
+// protocol over. It defaults to a real UDP socket; Service.newConn lets
+// tests substitute an in-memory one (see beacon-chain/p2p/simulations) so
+// discovery can be driven deterministically instead of over real sockets.
+func (s *Service) discv5Transport(ipAddr net.IP, port int) (net.PacketConn, error) {
+	if s.newConn != nil {
+		return s.newConn(ipAddr, port)
+	}
+	return net.ListenUDP("udp", &net.UDPAddr{IP: ipAddr, Port: port})
+}
+
+// createLocalNode builds the enode.LocalNode this service advertises,
+// resolving the externally reachable address through the configured NAT
+// mechanism (if any) and writing the current eth2 fork digest into it.
+func (s *Service) createLocalNode(privKey *ecdsa.PrivateKey, ipAddr net.IP, udpPort, tcpPort int) (*enode.LocalNode, error) {
+	db, err := enode.OpenDB("")
+	if err != nil {
+		return nil, err
+	}
+	localNode := enode.NewLocalNode(db, privKey)
+
+	externalIP := ipAddr
+	if s.nat != nil {
+		resolved, err := s.nat.ExternalIP()
+		if err != nil {
+			log.WithError(err).Warn("Could not resolve external IP via NAT, advertising bind address")
+		} else {
+			externalIP = resolved
+		}
+		go s.renewPortMapping(udpPort, tcpPort)
+	}
+
+	localNode.SetFallbackIP(externalIP)
+	localNode.SetFallbackUDP(udpPort)
+	localNode.Set(enr.WithEntry("tcp", uint16(tcpPort)))
+
+	if err := AddForkEntry(localNode, s.genesisTime, s.genesisValidatorsRoot); err != nil {
+		return nil, err
+	}
+	return localNode, nil
+}
+
+// serviceCtx returns s.ctx, or context.Background() if the service was
+// constructed directly (e.g. by a test) rather than through NewService and
+// never had one set. Background never cancels, so callers that only use it
+// to know when to stop simply run for the lifetime of the process instead.
+func (s *Service) serviceCtx() context.Context {
+	if s.ctx != nil {
+		return s.ctx
+	}
+	return context.Background()
+}
+
+// watchExternalIP periodically re-queries the configured NAT mechanism for
+// this node's external IP and renews its port mappings before their lease
+// expires. When the external IP changes -- for example a home router
+// renewing its DHCP lease from an ISP -- the local node's fallback address
+// is updated and its record is re-signed so peers pick up the new address.
+func (s *Service) watchExternalIP(localNode *enode.LocalNode) {
+	ctx := s.serviceCtx()
+	ticker := time.NewTicker(natRefreshInterval)
+	defer ticker.Stop()
+	last := localNode.Node().IP()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.renewPortMapping(int(s.cfg.UDPPort), int(s.cfg.TCPPort))
+
+			current, err := s.nat.ExternalIP()
+			if err != nil {
+				log.WithError(err).Warn("Could not refresh external IP")
+				continue
+			}
+			if !current.Equal(last) {
+				log.WithField("ip", current).Info("External IP changed, updating ENR")
+				localNode.SetFallbackIP(current)
+				localNode.SetFallbackUDP(int(s.cfg.UDPPort))
+				last = current
+			}
+		}
+	}
+}
+
+// renewPortMapping (re-)requests UDP and TCP port mappings for udpPort and
+// tcpPort from the configured NAT mechanism. It's called once up front when
+// the listener is created, and again on every watchExternalIP tick, so the
+// mapping's natMappingLifetime lease never lapses for as long as the
+// process runs.
+func (s *Service) renewPortMapping(udpPort, tcpPort int) {
+	if err := s.nat.AddMapping("udp", udpPort, udpPort, "prysm discovery", natMappingLifetime); err != nil {
+		log.WithError(err).Warn("Could not renew UDP port mapping")
+	}
+	if err := s.nat.AddMapping("tcp", tcpPort, tcpPort, "prysm p2p", natMappingLifetime); err != nil {
+		log.WithError(err).Warn("Could not renew TCP port mapping")
+	}
+}
+
+// startDiscoveryV5 starts a discv5 listener and seeds its routing table with
+// the configured bootstrap nodes.
+func (s *Service) startDiscoveryV5(ipAddr net.IP, privKey *ecdsa.PrivateKey) (*discover.UDPv5, error) {
+	listener := s.createListener(ipAddr, privKey)
+
+	bootNodes := make([]*enode.Node, 0, len(s.cfg.Discv5BootStrapAddr))
+	for _, addr := range s.cfg.Discv5BootStrapAddr {
+		bootNode, err := enode.Parse(enode.ValidSchemes, addr)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse bootstrap address %s: %w", addr, err)
+		}
+		bootNodes = append(bootNodes, bootNode)
+	}
+	if err := listener.SetFallbackNodes(bootNodes); err != nil {
+		return nil, err
+	}
+	return listener, nil
+}
+
+// processPeers filters candidate nodes down to ones whose eth2 ENR entry
+// matches our own fork digest, logging (but still including) peers that
+// match on fork digest yet advertise a different next scheduled fork.
+func (s *Service) processPeers(nodes []*enode.Node) []string {
+	ourDigest, err := CreateForkDigest(s.genesisTime, s.genesisValidatorsRoot)
+	if err != nil {
+		log.WithError(err).Error("Could not compute fork digest")
+		return nil
+	}
+
+	var multiAddrs []string
+	for _, n := range nodes {
+		enrForkID, err := retrieveForkEntry(n.Record())
+		if err != nil {
+			continue
+		}
+		if string(enrForkID.CurrentForkDigest) != string(ourDigest[:]) {
+			continue
+		}
+		if enrForkID.NextForkEpoch != params.BeaconConfig().NextForkEpoch {
+			log.WithField("peer", n.ID()).Debug("Peer matches fork digest but has different next fork epoch")
+		}
+		multiAddrs = append(multiAddrs, fmt.Sprintf("/ip4/%s/udp/%d/p2p-discv5/%s", n.IP(), n.UDP(), n.ID()))
+	}
+	return multiAddrs
+}
@@ -0,0 +1,50 @@
+package p2p
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+)
+
+// fakeNAT is a NAT implementation that always reports a canned external IP
+// and never actually maps a port, for use in tests that shouldn't touch a
+// real router.
+type fakeNAT struct {
+	ip net.IP
+}
+
+func (f *fakeNAT) ExternalIP() (net.IP, error) { return f.ip, nil }
+
+func (f *fakeNAT) AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) error {
+	return nil
+}
+
+func TestCreateListener_NATExtIP(t *testing.T) {
+	extIP := net.ParseIP("23.23.23.23")
+	pkey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &Service{
+		cfg:                   &Config{UDPPort: 7000},
+		nat:                   &fakeNAT{ip: extIP},
+		genesisTime:           time.Now(),
+		genesisValidatorsRoot: make([]byte, 32),
+	}
+
+	listener := s.createListener(net.ParseIP("127.0.0.1"), pkey)
+	defer listener.Close()
+
+	record := listener.Self().Record()
+	var gotIP enr.IPv4
+	if err := record.Load(&gotIP); err != nil {
+		t.Fatalf("Could not read ip entry from ENR: %v", err)
+	}
+	if !net.IP(gotIP).Equal(extIP) {
+		t.Errorf("Expected ENR ip entry %s, got %s", extIP, net.IP(gotIP))
+	}
+}
@@ -0,0 +1,93 @@
+package p2p
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/p2p/enr"
+	"github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/prysm/beacon-chain/core/helpers"
+	pb "github.com/prysmaticlabs/prysm/proto/beacon/p2p/v1"
+	"github.com/prysmaticlabs/prysm/shared/params"
+)
+
+// eth2ENRKey is the ENR key under which nodes advertise their current fork
+// digest and the next scheduled fork, per the eth2 discovery spec.
+const eth2ENRKey = "eth2"
+
+// CreateForkDigest computes the 4-byte fork digest for the fork version
+// active now on a chain whose genesis was at genesisTime, mixed with
+// genesisValidatorsRoot. It is exported so
+// callers outside this package (such as cmd/bootnode, which advertises an
+// eth2-aware ENR without running the rest of Service) can compute the same
+// digest peers will.
+func CreateForkDigest(genesisTime time.Time, genesisValidatorsRoot []byte) ([4]byte, error) {
+	return helpers.ComputeForkDigest(forkVersionAt(genesisTime), genesisValidatorsRoot)
+}
+
+// forkVersionAt returns the fork version active now on a chain whose genesis
+// was at genesisTime, taking the next scheduled fork into account once its
+// epoch has been reached.
+func forkVersionAt(genesisTime time.Time) []byte {
+	c := params.BeaconConfig()
+	if epochAt(genesisTime) >= c.NextForkEpoch {
+		return c.NextForkVersion
+	}
+	return c.GenesisForkVersion
+}
+
+// epochAt returns the epoch active at genesisTime, i.e. the epoch "now" is
+// in relative to that genesis.
+func epochAt(genesisTime time.Time) uint64 {
+	c := params.BeaconConfig()
+	if c.SecondsPerSlot == 0 || c.SlotsPerEpoch == 0 {
+		return 0
+	}
+	elapsed := time.Since(genesisTime)
+	if elapsed < 0 {
+		return 0
+	}
+	slot := uint64(elapsed.Seconds()) / c.SecondsPerSlot
+	return slot / c.SlotsPerEpoch
+}
+
+// AddForkEntry writes the eth2 ENR entry for the current fork digest and the
+// next scheduled fork version/epoch into localNode, replacing any prior
+// entry. It must be called with the node's actual *enode.LocalNode -- a
+// record obtained via enode.Node.Record() is a detached copy, and setting
+// an entry on it never reaches the node that gets advertised.
+func AddForkEntry(
+	localNode *enode.LocalNode,
+	genesisTime time.Time,
+	genesisValidatorsRoot []byte,
+) error {
+	digest, err := CreateForkDigest(genesisTime, genesisValidatorsRoot)
+	if err != nil {
+		return err
+	}
+	c := params.BeaconConfig()
+	enrForkID := &pb.ENRForkID{
+		CurrentForkDigest: digest[:],
+		NextForkVersion:   c.NextForkVersion,
+		NextForkEpoch:     c.NextForkEpoch,
+	}
+	enc, err := ssz.Marshal(enrForkID)
+	if err != nil {
+		return err
+	}
+	localNode.Set(enr.WithEntry(eth2ENRKey, enc))
+	return nil
+}
+
+// retrieveForkEntry reads and unmarshals the eth2 ENR entry from record.
+func retrieveForkEntry(record *enr.Record) (*pb.ENRForkID, error) {
+	var enc []byte
+	if err := record.Load(enr.WithEntry(eth2ENRKey, &enc)); err != nil {
+		return nil, err
+	}
+	enrForkID := &pb.ENRForkID{}
+	if err := ssz.Unmarshal(enc, enrForkID); err != nil {
+		return nil, err
+	}
+	return enrForkID, nil
+}
@@ -2,19 +2,23 @@ package p2p
 
 import (
 	"bytes"
+	"crypto/ecdsa"
 	"math/rand"
+	"net"
 	"os"
 	"path"
 	"strconv"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/p2p/discover"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/prysmaticlabs/go-ssz"
 	"github.com/prysmaticlabs/prysm/beacon-chain/core/helpers"
 	testDB "github.com/prysmaticlabs/prysm/beacon-chain/db/testing"
+	"github.com/prysmaticlabs/prysm/beacon-chain/p2p/simulations"
 	pb "github.com/prysmaticlabs/prysm/proto/beacon/p2p/v1"
 	"github.com/prysmaticlabs/prysm/shared/params"
 	"github.com/prysmaticlabs/prysm/shared/testutil"
@@ -22,19 +26,47 @@ import (
 	logTest "github.com/sirupsen/logrus/hooks/test"
 )
 
+// simDiscoveryService starts a discv5 listener for cfg over network's
+// in-memory transport instead of a real UDP socket, registers it with
+// network so it can take part in Connect, and returns the underlying
+// listener for callers that still want to drive it directly (Lookup,
+// Close, ...).
+func simDiscoveryService(t *testing.T, network *simulations.Network, adapter *simulations.SimAdapter, cfg *Config, genesisTime time.Time, genesisValidatorsRoot []byte, ipAddr net.IP, pkey *ecdsa.PrivateKey) *discover.UDPv5 {
+	t.Helper()
+	s := &Service{
+		cfg:                   cfg,
+		genesisTime:           genesisTime,
+		genesisValidatorsRoot: genesisValidatorsRoot,
+		newConn: func(ip net.IP, port int) (net.PacketConn, error) {
+			return adapter.Listen(ip, port), nil
+		},
+	}
+	listener, err := s.startDiscoveryV5(ipAddr, pkey)
+	if err != nil {
+		t.Fatalf("Could not start discovery for node: %v", err)
+	}
+	network.Register(listener.Self().ID(), simulations.NodeFromListener(listener))
+	return listener
+}
+
 func TestStartDiscv5_DifferentForkDigests(t *testing.T) {
 	db := testDB.SetupDB(t)
 	defer testDB.TeardownDB(t, db)
+
+	adapter := simulations.NewSimAdapter()
+	network := simulations.NewNetwork(adapter)
+	events := make(chan *simulations.Event, 16)
+	network.Subscribe(events)
+
 	port := 2000
-	ipAddr, pkey := createAddrAndPrivKey(t)
+	ipAddr := net.ParseIP("127.0.0.1")
+	pkey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
 	genesisTime := time.Now()
 	genesisValidatorsRoot := make([]byte, 32)
-	s := &Service{
-		cfg:                   &Config{UDPPort: uint(port)},
-		genesisTime:           genesisTime,
-		genesisValidatorsRoot: genesisValidatorsRoot,
-	}
-	bootListener := s.createListener(ipAddr, pkey)
+	bootListener := simDiscoveryService(t, network, adapter, &Config{UDPPort: uint(port)}, genesisTime, genesisValidatorsRoot, ipAddr, pkey)
 	defer bootListener.Close()
 
 	bootNode := bootListener.Self()
@@ -48,23 +80,25 @@ func TestStartDiscv5_DifferentForkDigests(t *testing.T) {
 	for i := 1; i <= 5; i++ {
 		port = 3000 + i
 		cfg.UDPPort = uint(port)
-		ipAddr, pkey := createAddrAndPrivKey(t)
+		pkey, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
 
 		// We give every peer a different genesis validators root, which
 		// will cause each peer to have a different ForkDigest, preventing
 		// them from connecting according to our discovery rules for eth2.
 		root := make([]byte, 32)
 		copy(root, strconv.Itoa(port))
-		s = &Service{
-			cfg:                   cfg,
-			genesisTime:           genesisTime,
-			genesisValidatorsRoot: root,
-		}
-		listener, err := s.startDiscoveryV5(ipAddr, pkey)
-		if err != nil {
-			t.Errorf("Could not start discovery for node: %v", err)
-		}
+		listener := simDiscoveryService(t, network, adapter, cfg, genesisTime, root, ipAddr, pkey)
 		listeners = append(listeners, listener)
+
+		// Connecting directly to the bootnode populates both sides'
+		// routing tables deterministically -- no sleep needed to let
+		// background UDP traffic catch up.
+		if err := network.Connect(listener.Self().ID(), bootNode.ID()); err != nil {
+			t.Fatalf("Could not connect to bootnode: %v", err)
+		}
 	}
 	defer func() {
 		// Close down all peers.
@@ -73,10 +107,16 @@ func TestStartDiscv5_DifferentForkDigests(t *testing.T) {
 		}
 	}()
 
-	// Wait for the nodes to have their local routing tables to be populated with the other nodes
-	time.Sleep(discoveryWaitTime)
-
 	lastListener := listeners[len(listeners)-1]
+	for _, listener := range listeners[:len(listeners)-1] {
+		if err := network.Connect(lastListener.Self().ID(), listener.Self().ID()); err != nil {
+			t.Fatalf("Could not connect peers: %v", err)
+		}
+	}
+	if len(events) == 0 {
+		t.Error("Expected Connect to emit PeerAdd events on the network's event channel")
+	}
+
 	nodes := lastListener.Lookup(bootNode.ID())
 	if len(nodes) < 4 {
 		t.Errorf("The node's local table doesn't have the expected number of nodes. "+
@@ -110,16 +150,19 @@ func TestStartDiscv5_SameForkDigests_DifferentNextForkData(t *testing.T) {
 	defer testDB.TeardownDB(t, db)
 	hook := logTest.NewGlobal()
 	logrus.SetLevel(logrus.DebugLevel)
+
+	adapter := simulations.NewSimAdapter()
+	network := simulations.NewNetwork(adapter)
+
 	port := 2000
-	ipAddr, pkey := createAddrAndPrivKey(t)
+	ipAddr := net.ParseIP("127.0.0.1")
+	pkey, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
 	genesisTime := time.Now()
 	genesisValidatorsRoot := make([]byte, 32)
-	s := &Service{
-		cfg:                   &Config{UDPPort: uint(port)},
-		genesisTime:           genesisTime,
-		genesisValidatorsRoot: genesisValidatorsRoot,
-	}
-	bootListener := s.createListener(ipAddr, pkey)
+	bootListener := simDiscoveryService(t, network, adapter, &Config{UDPPort: uint(port)}, genesisTime, genesisValidatorsRoot, ipAddr, pkey)
 	defer bootListener.Close()
 
 	bootNode := bootListener.Self()
@@ -135,26 +178,24 @@ func TestStartDiscv5_SameForkDigests_DifferentNextForkData(t *testing.T) {
 	for i := 1; i <= 5; i++ {
 		port = 3000 + i
 		cfg.UDPPort = uint(port)
-		ipAddr, pkey := createAddrAndPrivKey(t)
+		pkey, err := crypto.GenerateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
 
 		c := params.BeaconConfig()
 		nextForkEpoch := uint64(i)
 		c.NextForkEpoch = nextForkEpoch
 		params.OverrideBeaconConfig(c)
 
-		// We give every peer a different genesis validators root, which
-		// will cause each peer to have a different ForkDigest, preventing
-		// them from connecting according to our discovery rules for eth2.
-		s = &Service{
-			cfg:                   cfg,
-			genesisTime:           genesisTime,
-			genesisValidatorsRoot: genesisValidatorsRoot,
-		}
-		listener, err := s.startDiscoveryV5(ipAddr, pkey)
-		if err != nil {
-			t.Errorf("Could not start discovery for node: %v", err)
-		}
+		// Every peer shares the same genesis validators root, so they all
+		// share a fork digest -- only NextForkEpoch differs between them.
+		listener := simDiscoveryService(t, network, adapter, cfg, genesisTime, genesisValidatorsRoot, ipAddr, pkey)
 		listeners = append(listeners, listener)
+
+		if err := network.Connect(listener.Self().ID(), bootNode.ID()); err != nil {
+			t.Fatalf("Could not connect to bootnode: %v", err)
+		}
 	}
 	defer func() {
 		// Close down all peers.
@@ -163,10 +204,13 @@ func TestStartDiscv5_SameForkDigests_DifferentNextForkData(t *testing.T) {
 		}
 	}()
 
-	// Wait for the nodes to have their local routing tables to be populated with the other nodes
-	time.Sleep(discoveryWaitTime)
-
 	lastListener := listeners[len(listeners)-1]
+	for _, listener := range listeners[:len(listeners)-1] {
+		if err := network.Connect(lastListener.Self().ID(), listener.Self().ID()); err != nil {
+			t.Fatalf("Could not connect peers: %v", err)
+		}
+	}
+
 	nodes := lastListener.Lookup(bootNode.ID())
 	if len(nodes) < 4 {
 		t.Errorf("The node's local table doesn't have the expected number of nodes. "+
@@ -213,7 +257,7 @@ func TestDiscv5_AddRetrieveForkEntryENR(t *testing.T) {
 
 	genesisTime := time.Now()
 	genesisValidatorsRoot := make([]byte, 32)
-	digest, err := createForkDigest(genesisTime, make([]byte, 32))
+	digest, err := CreateForkDigest(genesisTime, make([]byte, 32))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,17 @@
+package p2p
+
+import "github.com/ethereum/go-ethereum/p2p/enode"
+
+// Listener is a slimmed down interface satisfied by discover.UDPv5, through
+// which Service holds its discv5 listener. discover.UDPv5 is its only
+// implementation here: the in-process simulation harness also runs
+// discover.UDPv5 and swaps only the underlying net.PacketConn (see
+// Service.newConn).
+type Listener interface {
+	Self() *enode.Node
+	Close()
+	Lookup(enode.ID) []*enode.Node
+	RandomNodes() enode.Iterator
+	Ping(*enode.Node) error
+	LocalNode() *enode.LocalNode
+}
@@ -0,0 +1,60 @@
+package p2p
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	gethnat "github.com/ethereum/go-ethereum/p2p/nat"
+)
+
+// NAT mirrors the small surface of go-ethereum's p2p/nat.Interface that the
+// discovery layer needs: a way to learn the address peers should dial, and a
+// way to punch a hole for it through a home router or carrier-grade NAT.
+type NAT interface {
+	ExternalIP() (net.IP, error)
+	AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) error
+}
+
+// natRefreshInterval is how often a running service re-queries its NAT
+// implementation for a (possibly changed) external IP, and re-issues its
+// port mappings.
+const natRefreshInterval = 15 * time.Minute
+
+// natMappingLifetime is the lease requested of the NAT device for each port
+// mapping. It's kept comfortably longer than natRefreshInterval so a mapping
+// never lapses between renewals.
+const natMappingLifetime = 20 * time.Minute
+
+// parseNAT turns a Config.NAT description into a NAT implementation,
+// returning nil for "" or "none" so callers can treat NAT traversal as
+// opt-in. The accepted vocabulary matches go-ethereum's -nat flag:
+// upnp, pmp, extip:<ip>, any, none.
+func parseNAT(desc string) (NAT, error) {
+	if desc == "" || desc == "none" {
+		return nil, nil
+	}
+	if strings.HasPrefix(desc, "extip:") {
+		ip := net.ParseIP(strings.TrimPrefix(desc, "extip:"))
+		if ip == nil {
+			return nil, fmt.Errorf("malformed IP in -nat extip: %s", desc)
+		}
+		return extIPNAT(ip), nil
+	}
+	iface, err := gethnat.Parse(desc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NAT mechanism %q: %w", desc, err)
+	}
+	return iface, nil
+}
+
+// extIPNAT is a NAT implementation for a statically configured external IP.
+// It never maps ports since the IP is assumed to already be reachable.
+type extIPNAT net.IP
+
+func (n extIPNAT) ExternalIP() (net.IP, error) { return net.IP(n), nil }
+
+func (n extIPNAT) AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) error {
+	return nil
+}
@@ -0,0 +1,93 @@
+package p2p
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"net"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.WithField("prefix", "p2p")
+
+// Service implements the discv5-backed peer discovery layer used by the
+// beacon node. It owns no chain state of its own -- genesisTime and
+// genesisValidatorsRoot are only used to compute the fork digest advertised
+// in (and required of) peer ENRs.
+type Service struct {
+	ctx                   context.Context
+	cancel                context.CancelFunc
+	cfg                   *Config
+	privKey               *ecdsa.PrivateKey
+	nat                   NAT
+	dv5Listener           Listener
+	genesisTime           time.Time
+	genesisValidatorsRoot []byte
+	startupErr            error
+
+	// newConn, when set, replaces the real UDP socket discv5Transport
+	// would otherwise open with a caller-supplied net.PacketConn. It
+	// exists so tests can run discovery over an in-memory transport (see
+	// beacon-chain/p2p/simulations) instead of real sockets.
+	newConn func(ipAddr net.IP, port int) (net.PacketConn, error)
+}
+
+// NewService initializes a new p2p service using the provided configuration.
+// Discovery is not started until Start is called.
+func NewService(cfg *Config) (*Service, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	priv, err := privKey(cfg)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	natm, err := parseNAT(cfg.NAT)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return &Service{
+		ctx:     ctx,
+		cancel:  cancel,
+		cfg:     cfg,
+		privKey: priv,
+		nat:     natm,
+	}, nil
+}
+
+// Start kicks off discv5 discovery using the service's configuration.
+func (s *Service) Start() {
+	listener, err := s.startDiscoveryV5(s.cfg.localIP(), s.privKey)
+	if err != nil {
+		log.WithError(err).Error("Could not start discv5")
+		s.startupErr = err
+		return
+	}
+	s.dv5Listener = listener
+}
+
+// Stop shuts the service down, closing the discv5 listener if one is
+// running.
+func (s *Service) Stop() error {
+	defer s.cancel()
+	if s.dv5Listener != nil {
+		s.dv5Listener.Close()
+	}
+	return nil
+}
+
+// localIP returns the configured local bind address, or "0.0.0.0" if unset.
+func (cfg *Config) localIP() net.IP {
+	if cfg.LocalIP == "" {
+		return net.ParseIP(ipAddr())
+	}
+	ip := net.ParseIP(cfg.LocalIP)
+	if ip == nil {
+		return net.ParseIP(ipAddr())
+	}
+	return ip
+}
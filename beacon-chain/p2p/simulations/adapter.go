@@ -0,0 +1,93 @@
+// Package simulations provides an in-process harness for running discv5
+// nodes against an in-memory transport instead of real UDP sockets, modeled
+// on go-ethereum's p2p/simulations and p2p/simulations/adapters packages.
+// It removes the routing-table-propagation timing that made the discv5
+// fork-digest tests flaky, and gives future gossipsub/subnet tests the same
+// deterministic surface to build on.
+package simulations
+
+import (
+	"crypto/ecdsa"
+	"net"
+
+	"github.com/ethereum/go-ethereum/p2p/discover"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// NodeConfig describes a single simulated discv5 node. LocalNode is built by
+// the caller (rather than by the adapter) so callers that need full control
+// over a node's ENR -- such as beacon-chain/p2p writing its eth2 fork-digest
+// entry -- can populate it before the node starts listening.
+type NodeConfig struct {
+	ID         enode.ID
+	PrivateKey *ecdsa.PrivateKey
+	LocalNode  *enode.LocalNode
+	IP         net.IP
+	Port       int
+}
+
+// Node is a running simulated node. It exposes just enough of
+// discover.UDPv5 for Network to drive discovery and for callers to tear it
+// down.
+type Node interface {
+	Listener() *discover.UDPv5
+	Close()
+}
+
+// Adapter creates the nodes that populate a Network. SimAdapter is the only
+// implementation today; it is an interface so a future adapter could run
+// nodes out-of-process the way go-ethereum's exec/docker adapters do.
+type Adapter interface {
+	NewNode(cfg *NodeConfig) (Node, error)
+}
+
+// SimAdapter creates nodes that all share a single in-memory hub, so any
+// node it creates can reach any other.
+type SimAdapter struct {
+	hub *hub
+}
+
+// NewSimAdapter returns a SimAdapter backed by a fresh, empty hub.
+func NewSimAdapter() *SimAdapter {
+	return &SimAdapter{hub: newHub()}
+}
+
+// Listen allocates an in-memory net.PacketConn for the given address. It is
+// exposed directly (in addition to NewNode) for callers that build their own
+// discv5 listener -- and thus their own ENR -- rather than going through
+// NewNode.
+func (a *SimAdapter) Listen(ip net.IP, port int) net.PacketConn {
+	return a.hub.listen(&net.UDPAddr{IP: ip, Port: port})
+}
+
+// NewNode starts a discv5 listener for cfg over an in-memory PacketConn.
+func (a *SimAdapter) NewNode(cfg *NodeConfig) (Node, error) {
+	conn := a.Listen(cfg.IP, cfg.Port)
+	listener, err := discover.ListenV5(conn, cfg.LocalNode, discover.Config{PrivateKey: cfg.PrivateKey})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &simNode{listener: listener, conn: conn}, nil
+}
+
+type simNode struct {
+	listener *discover.UDPv5
+	conn     net.PacketConn
+}
+
+func (n *simNode) Listener() *discover.UDPv5 { return n.listener }
+
+func (n *simNode) Close() {
+	n.listener.Close()
+	if n.conn != nil {
+		n.conn.Close()
+	}
+}
+
+// NodeFromListener wraps an already-running discv5 listener as a Node, for
+// callers that build the listener (and its ENR) themselves and only want
+// Network's Connect/Subscribe orchestration on top.
+func NodeFromListener(listener *discover.UDPv5) Node {
+	return &simNode{listener: listener}
+}
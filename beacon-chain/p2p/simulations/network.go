@@ -0,0 +1,117 @@
+package simulations
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// EventType categorizes an Event emitted by a Network.
+type EventType string
+
+const (
+	// PeerAdd is emitted once a Connect call between two nodes succeeds.
+	PeerAdd EventType = "PeerAdd"
+	// PeerDrop is the event type for a node leaving the network. Network
+	// has no operation that removes or closes a node, so it never emits
+	// PeerDrop.
+	PeerDrop EventType = "PeerDrop"
+)
+
+// Event describes a change in the relationship between two simulated nodes.
+type Event struct {
+	Type EventType
+	Node enode.ID
+	Peer enode.ID
+}
+
+// Network holds a set of simulated nodes and lets callers wire them
+// together deterministically, without depending on real-world UDP timing.
+type Network struct {
+	adapter Adapter
+
+	mu    sync.Mutex
+	nodes map[enode.ID]Node
+	subs  []chan *Event
+}
+
+// NewNetwork returns an empty Network whose nodes, when created through
+// NewNode, are produced by adapter.
+func NewNetwork(adapter Adapter) *Network {
+	return &Network{adapter: adapter, nodes: make(map[enode.ID]Node)}
+}
+
+// NewNode creates a node through the network's adapter and registers it
+// under cfg.ID.
+func (n *Network) NewNode(cfg *NodeConfig) (Node, error) {
+	node, err := n.adapter.NewNode(cfg)
+	if err != nil {
+		return nil, err
+	}
+	n.Register(cfg.ID, node)
+	return node, nil
+}
+
+// Register adds an externally created node to the network under id, so it
+// can participate in Connect/Subscribe like any node created via NewNode.
+func (n *Network) Register(id enode.ID, node Node) {
+	n.mu.Lock()
+	n.nodes[id] = node
+	n.mu.Unlock()
+}
+
+// Start is a lifecycle hook for adapters whose nodes need an explicit start
+// signal after creation. SimAdapter's nodes are already listening once
+// created, so this only verifies id is known; it exists for parity with
+// out-of-process adapters that do need an explicit start.
+func (n *Network) Start(id enode.ID) error {
+	n.mu.Lock()
+	_, ok := n.nodes[id]
+	n.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("simulations: unknown node %s", id)
+	}
+	return nil
+}
+
+// Connect pings idB from idA's discv5 listener. Ping blocks until a pong is
+// received (or the request errors out), so once Connect returns, both
+// nodes' routing tables are guaranteed to know about each other -- no sleep
+// required to let background UDP traffic catch up.
+func (n *Network) Connect(idA, idB enode.ID) error {
+	n.mu.Lock()
+	a, okA := n.nodes[idA]
+	b, okB := n.nodes[idB]
+	n.mu.Unlock()
+	if !okA || !okB {
+		return fmt.Errorf("simulations: unknown node pair %s/%s", idA, idB)
+	}
+	if err := a.Listener().Ping(b.Listener().Self()); err != nil {
+		return fmt.Errorf("simulations: could not connect %s to %s: %w", idA, idB, err)
+	}
+	n.emit(&Event{Type: PeerAdd, Node: idA, Peer: idB})
+	return nil
+}
+
+// Subscribe registers ch to receive events as they occur; today that is the
+// PeerAdd event each successful Connect emits.
+// Sends are non-blocking -- a slow subscriber drops events rather than
+// stalling Connect.
+func (n *Network) Subscribe(ch chan *Event) {
+	n.mu.Lock()
+	n.subs = append(n.subs, ch)
+	n.mu.Unlock()
+}
+
+func (n *Network) emit(ev *Event) {
+	n.mu.Lock()
+	subs := append([]chan *Event(nil), n.subs...)
+	n.mu.Unlock()
+	for _, ch := range subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
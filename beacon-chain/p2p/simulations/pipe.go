@@ -0,0 +1,109 @@
+package simulations
+
+import (
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// hub routes UDP-shaped datagrams between simulated nodes entirely in
+// memory, keyed by the synthetic address each node was allocated. It plays
+// the role a physical switch (or the kernel's loopback UDP stack) would play
+// for real sockets, without any of the real-world scheduling jitter that
+// makes discovery tests flaky.
+type hub struct {
+	mu    sync.Mutex
+	conns map[string]*simPacketConn
+}
+
+func newHub() *hub {
+	return &hub{conns: make(map[string]*simPacketConn)}
+}
+
+func (h *hub) listen(addr *net.UDPAddr) *simPacketConn {
+	conn := &simPacketConn{
+		hub:    h,
+		addr:   addr,
+		inbox:  make(chan packet, 256),
+		closed: make(chan struct{}),
+	}
+	h.mu.Lock()
+	h.conns[addr.String()] = conn
+	h.mu.Unlock()
+	return conn
+}
+
+func (h *hub) send(to *net.UDPAddr, pkt packet) error {
+	h.mu.Lock()
+	dst, ok := h.conns[to.String()]
+	h.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("simulations: no node listening on %s", to)
+	}
+	select {
+	case dst.inbox <- pkt:
+		return nil
+	case <-dst.closed:
+		return fmt.Errorf("simulations: %s is closed", to)
+	}
+}
+
+func (h *hub) remove(addr *net.UDPAddr) {
+	h.mu.Lock()
+	delete(h.conns, addr.String())
+	h.mu.Unlock()
+}
+
+type packet struct {
+	from *net.UDPAddr
+	data []byte
+}
+
+// simPacketConn is a net.PacketConn backed by hub, used in place of a real
+// *net.UDPConn so discv5 can run, unmodified, against an in-memory network.
+type simPacketConn struct {
+	hub    *hub
+	addr   *net.UDPAddr
+	inbox  chan packet
+	closed chan struct{}
+	once   sync.Once
+}
+
+func (c *simPacketConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	select {
+	case pkt := <-c.inbox:
+		n := copy(b, pkt.data)
+		return n, pkt.from, nil
+	case <-c.closed:
+		return 0, nil, fmt.Errorf("simulations: read from closed conn %s", c.addr)
+	}
+}
+
+func (c *simPacketConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	udpAddr, ok := addr.(*net.UDPAddr)
+	if !ok {
+		return 0, fmt.Errorf("simulations: unsupported address type %T", addr)
+	}
+	if err := c.hub.send(udpAddr, packet{from: c.addr, data: b}); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c *simPacketConn) Close() error {
+	c.once.Do(func() {
+		c.hub.remove(c.addr)
+		close(c.closed)
+	})
+	return nil
+}
+
+func (c *simPacketConn) LocalAddr() net.Addr { return c.addr }
+
+// Deadlines are meaningless for an in-memory conn that never blocks longer
+// than a channel send/receive; treated as no-ops so simPacketConn still
+// satisfies net.PacketConn.
+func (c *simPacketConn) SetDeadline(t time.Time) error      { return nil }
+func (c *simPacketConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *simPacketConn) SetWriteDeadline(t time.Time) error { return nil }
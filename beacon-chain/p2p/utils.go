@@ -0,0 +1,38 @@
+package p2p
+
+import (
+	"crypto/ecdsa"
+	"path"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const keyPath = "network-keys"
+
+// privKey loads a persistent ECDSA private key from cfg.DataDir, generating
+// and persisting a new one on first run. An empty DataDir yields a fresh,
+// non-persistent key -- used by tests and by nodes that don't care about a
+// stable node ID across restarts.
+func privKey(cfg *Config) (*ecdsa.PrivateKey, error) {
+	if cfg.DataDir == "" {
+		return crypto.GenerateKey()
+	}
+	defaultKeyPath := path.Join(cfg.DataDir, keyPath)
+	if _, err := crypto.LoadECDSA(defaultKeyPath); err == nil {
+		return crypto.LoadECDSA(defaultKeyPath)
+	}
+	priv, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if err := crypto.SaveECDSA(defaultKeyPath, priv); err != nil {
+		return nil, err
+	}
+	return priv, nil
+}
+
+// ipAddr resolves the local bind address to use when none is specified in
+// the node's configuration.
+func ipAddr() (localIP string) {
+	return "0.0.0.0"
+}
@@ -0,0 +1,188 @@
+// Package main implements a standalone eth2 discv5 bootnode.
+//
+// Unlike a full beacon node, this binary does not sync, validate, or keep any
+// chain state. It exists purely to seed discv5 routing tables for other
+// peers, in the same spirit as go-ethereum's cmd/bootnode. It still needs to
+// be fork-digest aware so that peers which filter candidates by ENR fork
+// entry will accept it as a valid bootstrap address.
+package main
+
+import (
+	"crypto/ecdsa"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/discover"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	gethlog "github.com/ethereum/go-ethereum/log"
+	"github.com/prysmaticlabs/prysm/beacon-chain/p2p"
+	"github.com/prysmaticlabs/prysm/shared/params"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.WithField("prefix", "bootnode")
+
+var (
+	debugFlag                 = flag.Bool("debug", false, "Enable debug logging")
+	privateKeyFlag            = flag.String("nodekey", "", "Private key file path of this node")
+	privateKeyHexFlag         = flag.String("nodekeyhex", "", "Hex-encoded private key of this node")
+	genKeyFlag                = flag.String("genkey", "", "Generate a node key and write it to the given file")
+	addrFlag                  = flag.String("addr", ":4000", "Listening address for the discv5 UDP socket")
+	natFlag                   = flag.String("natdesc", "none", "NAT port mapping mechanism (any|none|upnp|pmp|extip:<IP>)")
+	forkVersionFlag           = flag.String("fork-version", "", "Hex-encoded current fork version, defaults to the genesis fork version")
+	genesisTimeFlag           = flag.Uint64("genesis-time", 0, "Unix timestamp of genesis, defaults to now")
+	genesisValidatorsRootFlag = flag.String("genesis-validators-root", "", "Hex-encoded genesis validators root, defaults to 32 zero bytes")
+)
+
+func main() {
+	flag.Parse()
+
+	if *genKeyFlag != "" {
+		if err := genNodeKey(*genKeyFlag); err != nil {
+			log.Fatalf("Could not generate node key: %v", err)
+		}
+		return
+	}
+
+	if *debugFlag {
+		logrus.SetLevel(logrus.DebugLevel)
+		gethlog.Root().SetHandler(gethlog.LvlFilterHandler(gethlog.LvlDebug, gethlog.StreamHandler(os.Stderr, gethlog.TerminalFormat(false))))
+	}
+
+	privKey, err := loadOrGeneratePrivateKey()
+	if err != nil {
+		log.Fatalf("Could not load node key: %v", err)
+	}
+
+	natm, err := parseNAT(*natFlag)
+	if err != nil {
+		log.Fatalf("Could not parse -natdesc: %v", err)
+	}
+
+	genesisTime := time.Now()
+	if *genesisTimeFlag != 0 {
+		genesisTime = time.Unix(int64(*genesisTimeFlag), 0)
+	}
+
+	genesisValidatorsRoot := make([]byte, 32)
+	if *genesisValidatorsRootFlag != "" {
+		root, err := decodeHex(*genesisValidatorsRootFlag)
+		if err != nil {
+			log.Fatalf("Could not decode -genesis-validators-root: %v", err)
+		}
+		genesisValidatorsRoot = root
+	}
+
+	if *forkVersionFlag != "" {
+		fv, err := decodeHex(*forkVersionFlag)
+		if err != nil {
+			log.Fatalf("Could not decode -fork-version: %v", err)
+		}
+		c := params.BeaconConfig()
+		c.GenesisForkVersion = fv
+		params.OverrideBeaconConfig(c)
+	}
+
+	udpAddr, err := net.ResolveUDPAddr("udp", *addrFlag)
+	if err != nil {
+		log.Fatalf("Could not resolve -addr: %v", err)
+	}
+	conn, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		log.Fatalf("Could not listen on %s: %v", *addrFlag, err)
+	}
+
+	localIP, err := resolveExternalIP(natm, udpAddr)
+	if err != nil {
+		log.Warnf("Could not determine external IP, falling back to loopback: %v", err)
+		localIP = net.ParseIP("127.0.0.1")
+	}
+
+	db, err := enode.OpenDB("")
+	if err != nil {
+		log.Fatalf("Could not open node database: %v", err)
+	}
+	localNode := enode.NewLocalNode(db, privKey)
+	localNode.SetFallbackIP(localIP)
+	localNode.SetFallbackUDP(udpAddr.Port)
+
+	if err := p2p.AddForkEntry(localNode, genesisTime, genesisValidatorsRoot); err != nil {
+		log.Fatalf("Could not set eth2 ENR entry: %v", err)
+	}
+
+	listenerCfg := discover.Config{PrivateKey: privKey}
+	listener, err := discover.ListenV5(conn, localNode, listenerCfg)
+	if err != nil {
+		log.Fatalf("Could not start discv5 listener: %v", err)
+	}
+	defer listener.Close()
+
+	if natm != nil {
+		go natm.keepMapped(udpAddr.Port, udpAddr.Port)
+	}
+
+	printSelf(localNode)
+
+	refreshTicker := time.NewTicker(time.Duration(params.BeaconConfig().SecondsPerSlot) * time.Second)
+	defer refreshTicker.Stop()
+	for range refreshTicker.C {
+		if err := p2p.AddForkEntry(localNode, genesisTime, genesisValidatorsRoot); err != nil {
+			log.Errorf("Could not refresh eth2 ENR entry: %v", err)
+		}
+	}
+}
+
+// resolveExternalIP returns the address peers should dial to reach this
+// node. If NAT traversal found (or was configured with) an external IP,
+// that takes priority; otherwise, a bind address that isn't "any interface"
+// is usable as-is (the common case for a bootnode running directly on a
+// box with a public IP and no NAT in the way).
+func resolveExternalIP(natm *natMapper, udpAddr *net.UDPAddr) (net.IP, error) {
+	if natm != nil {
+		ip, err := natm.ExternalIP()
+		if err == nil {
+			return ip, nil
+		}
+		log.WithError(err).Warn("Could not resolve external IP via NAT")
+	}
+	if udpAddr.IP != nil && !udpAddr.IP.IsUnspecified() {
+		return udpAddr.IP, nil
+	}
+	return nil, fmt.Errorf("no usable bind address, and NAT traversal is disabled or failed")
+}
+
+func printSelf(localNode *enode.LocalNode) {
+	fmt.Println(localNode.Node().String())
+	fmt.Println(localNode.Node().URLv4())
+}
+
+func loadOrGeneratePrivateKey() (*ecdsa.PrivateKey, error) {
+	switch {
+	case *privateKeyHexFlag != "":
+		return crypto.HexToECDSA(*privateKeyHexFlag)
+	case *privateKeyFlag != "":
+		return crypto.LoadECDSA(*privateKeyFlag)
+	default:
+		return crypto.GenerateKey()
+	}
+}
+
+func genNodeKey(path string) error {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return err
+	}
+	return crypto.SaveECDSA(path, key)
+}
+
+func decodeHex(s string) ([]byte, error) {
+	if len(s) >= 2 && s[0:2] == "0x" {
+		s = s[2:]
+	}
+	return hex.DecodeString(s)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/nat"
+)
+
+// natMapper wraps a go-ethereum nat.Interface so the bootnode can keep its
+// port mappings refreshed for as long as the process runs. A TCP mapping is
+// requested alongside the UDP one every time, whether or not anything listens
+// on that TCP port.
+type natMapper struct {
+	nat.Interface
+}
+
+// parseNAT turns a -natdesc value (any|none|upnp|pmp|extip:<ip>) into a
+// natMapper, or nil for "none". This accepts the same vocabulary as
+// go-ethereum's -nat flag.
+func parseNAT(desc string) (*natMapper, error) {
+	if desc == "" || desc == "none" {
+		return nil, nil
+	}
+	iface, err := nat.Parse(desc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid -natdesc %q: %w", desc, err)
+	}
+	return &natMapper{Interface: iface}, nil
+}
+
+// natMappingLifetime is the lease requested of the NAT device for each port
+// mapping. natRefreshRate is kept comfortably shorter so keepMapped always
+// renews the lease well before it expires.
+const (
+	natMappingLifetime = 20 * time.Minute
+	natRefreshRate     = 15 * time.Minute
+)
+
+// mapPorts requests UDP and TCP port mappings for the lifetime of the
+// lease, matching beacon-chain/p2p's own discovery and libp2p listeners.
+func (n *natMapper) mapPorts(udpPort, tcpPort int) {
+	if err := n.AddMapping("udp", udpPort, udpPort, "prysm discovery", natMappingLifetime); err != nil {
+		log.Warnf("Could not add UDP port mapping: %v", err)
+	}
+	if err := n.AddMapping("tcp", tcpPort, tcpPort, "prysm bootnode", natMappingLifetime); err != nil {
+		log.Warnf("Could not add TCP port mapping: %v", err)
+	}
+}
+
+// keepMapped requests the port mappings immediately, then renews them every
+// natRefreshRate for as long as the process runs, so the lease never lapses.
+func (n *natMapper) keepMapped(udpPort, tcpPort int) {
+	n.mapPorts(udpPort, tcpPort)
+	ticker := time.NewTicker(natRefreshRate)
+	defer ticker.Stop()
+	for range ticker.C {
+		n.mapPorts(udpPort, tcpPort)
+	}
+}